@@ -0,0 +1,13 @@
+// Copyright 2021 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package comid
+
+// TaggedURI is a URI. It has no CBOR methods of its own, so it is encoded
+// as a plain CBOR text string: no tag 32 (RFC 8949 §3.4.5) is emitted.
+type TaggedURI string
+
+// Empty returns true if the TaggedURI has the zero value.
+func (o TaggedURI) Empty() bool {
+	return o == ""
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package corim
+
+import (
+	"github.com/fxamacker/cbor/v2"
+	"github.com/veraison/corim/comid"
+)
+
+// UnsignedCorim is the top-level, unsigned CoRIM document.
+type UnsignedCorim struct {
+	Entities Entities `cbor:"4,keyasint,omitempty" json:"entities,omitempty"`
+}
+
+// Option configures Unmarshal.
+type Option func(*unmarshalConfig)
+
+type unmarshalConfig struct {
+	profile *Profile
+}
+
+// WithProfile scopes Unmarshal's entity name and role decoding to p instead
+// of the default profile, so that custom types registered against p do not
+// need to be registered process-wide via RegisterEntityNameType or
+// RegisterRoleType.
+func WithProfile(p *Profile) Option {
+	return func(c *unmarshalConfig) {
+		c.profile = p
+	}
+}
+
+// Unmarshal decodes a CBOR-encoded unsigned CoRIM from data into o. By
+// default, entity names are resolved against the process-wide default
+// profile and roles are decoded by numeric code without checking them
+// against any registry. With WithProfile, entity names and roles are
+// resolved against that Profile instead, and a role it does not know makes
+// Unmarshal fail.
+func (o *UnsignedCorim) Unmarshal(data []byte, opts ...Option) error {
+	cfg := unmarshalConfig{profile: defaultProfile}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.profile == defaultProfile {
+		return cbor.Unmarshal(data, o)
+	}
+
+	var raw struct {
+		Entities []struct {
+			EntityName  cbor.RawMessage   `cbor:"0,keyasint"`
+			RegID       cbor.RawMessage   `cbor:"1,keyasint,omitempty"`
+			Roles       []cbor.RawMessage `cbor:"2,keyasint"`
+			Credentials Credentials       `cbor:"3,keyasint,omitempty"`
+		} `cbor:"4,keyasint,omitempty"`
+	}
+	if err := cbor.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	dec := cfg.profile.NewDecoder()
+
+	entities := make(Entities, 0, len(raw.Entities))
+	for _, re := range raw.Entities {
+		en, err := dec.DecodeEntityName(re.EntityName)
+		if err != nil {
+			return err
+		}
+
+		e := Entity{EntityName: en}
+
+		if len(re.RegID) > 0 {
+			var regID comid.TaggedURI
+			if err := cbor.Unmarshal(re.RegID, &regID); err != nil {
+				return err
+			}
+			e.RegID = &regID
+		}
+
+		for _, rr := range re.Roles {
+			role, err := dec.DecodeRole(rr)
+			if err != nil {
+				return err
+			}
+			e.Roles = append(e.Roles, role)
+		}
+
+		e.Credentials = re.Credentials
+
+		entities = append(entities, e)
+	}
+
+	o.Entities = entities
+
+	return nil
+}
@@ -0,0 +1,35 @@
+// Copyright 2021 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package corim
+
+import (
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnsignedCorim_Unmarshal_WithProfile_keeps_credentials(t *testing.T) {
+	e := NewEntity().
+		SetEntityName("ACME Ltd.").
+		SetRegID("http://acme.example").
+		SetRoles(RoleManifestCreator)
+	e.SetCOSEKey([]byte{0xa1, 0x01, 0x02})
+
+	uc := UnsignedCorim{Entities: *NewEntities().AddEntity(*e)}
+
+	data, err := cbor.Marshal(&uc)
+	require.NoError(t, err)
+
+	var byDefault UnsignedCorim
+	require.NoError(t, byDefault.Unmarshal(data))
+	require.Len(t, byDefault.Entities, 1)
+	assert.Len(t, byDefault.Entities[0].Credentials, 1)
+
+	var byProfile UnsignedCorim
+	require.NoError(t, byProfile.Unmarshal(data, WithProfile(NewProfile())))
+	require.Len(t, byProfile.Entities, 1)
+	assert.Equal(t, byDefault.Entities[0].Credentials, byProfile.Entities[0].Credentials)
+}
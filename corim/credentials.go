@@ -0,0 +1,259 @@
+// Copyright 2021 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package corim
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// CredentialKind identifies the kind of verifiable credential carried by a
+// Credential.
+type CredentialKind uint8
+
+const (
+	// CredentialKindX509Certificate holds the DER bytes of a single
+	// X.509 certificate.
+	CredentialKindX509Certificate CredentialKind = iota + 1
+	// CredentialKindX509CertificateChain holds the concatenated DER
+	// bytes of an X.509 certificate chain, leaf first.
+	CredentialKindX509CertificateChain
+	// CredentialKindCOSEKey holds a CBOR-encoded COSE_Key (RFC 9052 §7).
+	CredentialKindCOSEKey
+	// CredentialKindJWK holds a JSON-encoded JSON Web Key (RFC 7517).
+	CredentialKindJWK
+)
+
+// Credential binds an Entity to a verifiable credential, so that a
+// signature on the COSE envelope enclosing a CoRIM can be traced to the
+// Entity record itself.
+type Credential struct {
+	Kind CredentialKind `cbor:"0,keyasint" json:"kind"`
+	// Value holds the credential's raw bytes. For the JSON serialization
+	// of CredentialKindJWK, it is emitted and parsed as a nested JSON
+	// object rather than a base64 string; see MarshalJSON.
+	Value []byte `cbor:"1,keyasint" json:"value"`
+
+	// leaf is the parsed leaf certificate for the X.509 kinds, cached by
+	// SetX509Cert/AddCertChain so that Valid does not need to re-parse
+	// Value on every call.
+	leaf *x509.Certificate
+}
+
+// Valid checks that the credential is well-formed: the leaf certificate (if
+// any) must parse, and, for the X.509 kinds, its Subject Common Name must
+// match name when name wraps a StringEntityName.
+func (o Credential) Valid(name *EntityName) error {
+	switch o.Kind {
+	case CredentialKindX509Certificate, CredentialKindX509CertificateChain:
+		leaf := o.leaf
+		if leaf == nil {
+			var err error
+			leaf, err = o.parseLeaf()
+			if err != nil {
+				return fmt.Errorf("parsing leaf certificate: %w", err)
+			}
+		}
+
+		if name != nil {
+			if sev, ok := name.GetCachedValue().(*StringEntityName); ok {
+				if leaf.Subject.CommonName != string(*sev) {
+					return fmt.Errorf(
+						"certificate subject %q does not match entity name %q",
+						leaf.Subject.CommonName, string(*sev),
+					)
+				}
+			}
+		}
+	case CredentialKindCOSEKey, CredentialKindJWK:
+		if len(o.Value) == 0 {
+			return errors.New("empty key material")
+		}
+	default:
+		return fmt.Errorf("unknown credential kind %d", o.Kind)
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes the credential. For CredentialKindJWK, Value is
+// emitted as a nested JSON object (Value must hold a JSON-encoded JWK, per
+// SetJWK) rather than a base64 string, so that JSON tooling can read the
+// key directly; every other kind falls back to the default []byte
+// encoding.
+func (o Credential) MarshalJSON() ([]byte, error) {
+	if o.Kind == CredentialKindJWK {
+		return json.Marshal(struct {
+			Kind  CredentialKind  `json:"kind"`
+			Value json.RawMessage `json:"value"`
+		}{Kind: o.Kind, Value: o.Value})
+	}
+
+	type alias Credential
+	return json.Marshal(alias(o))
+}
+
+// UnmarshalJSON decodes the credential, reading Value as a nested JSON
+// object for CredentialKindJWK and as a base64 string otherwise, mirroring
+// MarshalJSON.
+func (o *Credential) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Kind  CredentialKind  `json:"kind"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	o.Kind = raw.Kind
+	o.leaf = nil
+
+	if raw.Kind == CredentialKindJWK {
+		o.Value = append([]byte(nil), raw.Value...)
+		return nil
+	}
+
+	return json.Unmarshal(raw.Value, &o.Value)
+}
+
+// parseLeaf parses the leaf certificate out of Value, for credentials that
+// were not built via SetX509Cert/AddCertChain (e.g. decoded off the wire,
+// where leaf is never populated). For CredentialKindX509CertificateChain,
+// Value is the concatenation of the chain's DER certificates, leaf first.
+func (o Credential) parseLeaf() (*x509.Certificate, error) {
+	switch o.Kind {
+	case CredentialKindX509Certificate:
+		return x509.ParseCertificate(o.Value)
+	case CredentialKindX509CertificateChain:
+		certs, err := x509.ParseCertificates(o.Value)
+		if err != nil {
+			return nil, err
+		}
+		if len(certs) == 0 {
+			return nil, errors.New("empty certificate chain")
+		}
+		return certs[0], nil
+	default:
+		return nil, fmt.Errorf("unexpected credential kind %d", o.Kind)
+	}
+}
+
+// Credentials is a list of Credential records carried by an Entity.
+type Credentials []Credential
+
+// Valid checks that every Credential in the list is well-formed relative to
+// name, the EntityName of the Entity that carries them.
+func (o Credentials) Valid(name *EntityName) error {
+	for i, c := range o {
+		if err := c.Valid(name); err != nil {
+			return fmt.Errorf("credential at index %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// SetX509Cert appends a Credential wrapping the leaf certificate der (DER
+// encoded). der is parsed immediately so that malformed input is rejected
+// at build time rather than at Valid time.
+func (o *Entity) SetX509Cert(der []byte) (*Entity, error) {
+	if o == nil {
+		return nil, nil
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("parsing X.509 certificate: %w", err)
+	}
+
+	o.Credentials = append(o.Credentials, Credential{
+		Kind:  CredentialKindX509Certificate,
+		Value: der,
+		leaf:  cert,
+	})
+
+	return o, nil
+}
+
+// AddCertChain appends a Credential wrapping an X.509 certificate chain,
+// ders, leaf certificate first. The leaf is parsed immediately.
+func (o *Entity) AddCertChain(ders [][]byte) (*Entity, error) {
+	if o == nil {
+		return nil, nil
+	}
+
+	if len(ders) == 0 {
+		return nil, errors.New("empty certificate chain")
+	}
+
+	leaf, err := x509.ParseCertificate(ders[0])
+	if err != nil {
+		return nil, fmt.Errorf("parsing leaf certificate: %w", err)
+	}
+
+	var chain []byte
+	for _, der := range ders {
+		chain = append(chain, der...)
+	}
+
+	o.Credentials = append(o.Credentials, Credential{
+		Kind:  CredentialKindX509CertificateChain,
+		Value: chain,
+		leaf:  leaf,
+	})
+
+	return o, nil
+}
+
+// SetCOSEKey appends a Credential wrapping a CBOR-encoded COSE_Key.
+func (o *Entity) SetCOSEKey(key []byte) *Entity {
+	if o == nil {
+		return nil
+	}
+
+	o.Credentials = append(o.Credentials, Credential{Kind: CredentialKindCOSEKey, Value: key})
+
+	return o
+}
+
+// SetJWK appends a Credential wrapping a JSON-encoded JWK.
+func (o *Entity) SetJWK(jwk []byte) *Entity {
+	if o == nil {
+		return nil
+	}
+
+	o.Credentials = append(o.Credentials, Credential{Kind: CredentialKindJWK, Value: jwk})
+
+	return o
+}
+
+// FindManifestCreatorCredential locates the Entity with role
+// RoleManifestCreator in o and returns its key-bearing Credential (a
+// CredentialKindCOSEKey or CredentialKindJWK entry), if any. COSE signature
+// verification code can use the returned Credential as a trust hint when
+// resolving the public key for a signed CoRIM.
+//
+// This helper only locates the candidate key credential; nothing in this
+// package passes it to a COSE verifier.
+func (o Entities) FindManifestCreatorCredential() (*Credential, error) {
+	for i := range o {
+		for _, r := range o[i].Roles {
+			if r != RoleManifestCreator {
+				continue
+			}
+
+			for j := range o[i].Credentials {
+				switch o[i].Credentials[j].Kind {
+				case CredentialKindCOSEKey, CredentialKindJWK:
+					return &o[i].Credentials[j], nil
+				}
+			}
+
+			return nil, nil
+		}
+	}
+
+	return nil, errors.New("no entity with role manifestCreator")
+}
@@ -0,0 +1,218 @@
+// Copyright 2021 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package corim
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateTestCert(t *testing.T, cn string) []byte {
+	t.Helper()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Unix(0, 0),
+		NotAfter:     time.Unix(0, 0).Add(24 * time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	require.NoError(t, err)
+
+	return der
+}
+
+func TestEntity_SetX509Cert(t *testing.T) {
+	der := generateTestCert(t, "ACME Ltd.")
+
+	e := NewEntity().
+		SetEntityName("ACME Ltd.").
+		SetRegID("http://acme.example").
+		SetRoles(RoleManifestCreator)
+
+	_, err := e.SetX509Cert(der)
+	require.NoError(t, err)
+
+	assert.NoError(t, e.Valid())
+}
+
+func TestEntity_SetX509Cert_invalid_der(t *testing.T) {
+	e := NewEntity()
+
+	_, err := e.SetX509Cert([]byte("not a cert"))
+	assert.ErrorContains(t, err, "parsing X.509 certificate")
+}
+
+func TestEntity_SetX509Cert_subject_mismatch(t *testing.T) {
+	der := generateTestCert(t, "Other Org")
+
+	e := NewEntity().
+		SetEntityName("ACME Ltd.").
+		SetRegID("http://acme.example").
+		SetRoles(RoleManifestCreator)
+
+	_, err := e.SetX509Cert(der)
+	require.NoError(t, err)
+
+	err = e.Valid()
+	assert.EqualError(t, err,
+		`invalid entity: credential at index 0: certificate subject "Other Org" does not match entity name "ACME Ltd."`)
+}
+
+func TestCredential_Valid_decoded_from_wire(t *testing.T) {
+	der := generateTestCert(t, "ACME Ltd.")
+
+	// Simulate a Credential obtained by decoding a CoRIM off the wire:
+	// Value is populated but the builder-only leaf cache is not.
+	c := Credential{Kind: CredentialKindX509Certificate, Value: der}
+
+	name := MustNewStringEntityName("ACME Ltd.")
+	assert.NoError(t, c.Valid(name))
+}
+
+func TestCredential_Valid_decoded_from_wire_chain(t *testing.T) {
+	leaf := generateTestCert(t, "ACME Ltd.")
+	root := generateTestCert(t, "ACME Root CA")
+
+	c := Credential{Kind: CredentialKindX509CertificateChain, Value: append(append([]byte{}, leaf...), root...)}
+
+	name := MustNewStringEntityName("ACME Ltd.")
+	assert.NoError(t, c.Valid(name))
+}
+
+func TestCredential_Valid_decoded_from_wire_mismatch(t *testing.T) {
+	der := generateTestCert(t, "Other Org")
+
+	c := Credential{Kind: CredentialKindX509Certificate, Value: der}
+
+	name := MustNewStringEntityName("ACME Ltd.")
+	assert.EqualError(t, c.Valid(name),
+		`certificate subject "Other Org" does not match entity name "ACME Ltd."`)
+}
+
+func TestCredential_MarshalJSON_JWK_is_nested_object(t *testing.T) {
+	c := Credential{Kind: CredentialKindJWK, Value: []byte(`{"kty":"EC"}`)}
+
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"kind":4,"value":{"kty":"EC"}}`, string(data))
+
+	var out Credential
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, c.Kind, out.Kind)
+	assert.JSONEq(t, string(c.Value), string(out.Value))
+}
+
+func TestCredential_MarshalJSON_X509_is_base64(t *testing.T) {
+	c := Credential{Kind: CredentialKindX509Certificate, Value: []byte{0x01, 0x02, 0x03}}
+
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"kind":1,"value":"AQID"}`, string(data))
+
+	var out Credential
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, c.Kind, out.Kind)
+	assert.Equal(t, c.Value, out.Value)
+}
+
+func TestEntity_AddCertChain(t *testing.T) {
+	leaf := generateTestCert(t, "ACME Ltd.")
+	root := generateTestCert(t, "ACME Root CA")
+
+	e := NewEntity().
+		SetEntityName("ACME Ltd.").
+		SetRegID("http://acme.example").
+		SetRoles(RoleManifestCreator)
+
+	_, err := e.AddCertChain([][]byte{leaf, root})
+	require.NoError(t, err)
+
+	assert.NoError(t, e.Valid())
+}
+
+func TestEntity_AddCertChain_empty(t *testing.T) {
+	e := NewEntity()
+
+	_, err := e.AddCertChain(nil)
+	assert.EqualError(t, err, "empty certificate chain")
+}
+
+func TestEntity_SetCOSEKey_and_SetJWK(t *testing.T) {
+	e := NewEntity().
+		SetEntityName("ACME Ltd.").
+		SetRegID("http://acme.example").
+		SetRoles(RoleManifestCreator)
+
+	e.SetCOSEKey([]byte{0xa1, 0x01, 0x02}).SetJWK([]byte(`{"kty":"EC"}`))
+
+	require.Len(t, e.Credentials, 2)
+	assert.NoError(t, e.Valid())
+}
+
+func TestEntities_FindManifestCreatorCredential(t *testing.T) {
+	der := generateTestCert(t, "ACME Ltd.")
+
+	e := NewEntity().
+		SetEntityName("ACME Ltd.").
+		SetRegID("http://acme.example").
+		SetRoles(RoleManifestCreator)
+
+	// The X.509 certificate is not key material on its own, so it must
+	// be skipped in favour of the COSE_Key that follows it.
+	_, err := e.SetX509Cert(der)
+	require.NoError(t, err)
+	e.SetCOSEKey([]byte{0xa1, 0x01, 0x02})
+
+	es := NewEntities().AddEntity(*e)
+
+	cred, err := es.FindManifestCreatorCredential()
+	require.NoError(t, err)
+	require.NotNil(t, cred)
+	assert.Equal(t, CredentialKindCOSEKey, cred.Kind)
+}
+
+func TestEntities_FindManifestCreatorCredential_no_key_material(t *testing.T) {
+	der := generateTestCert(t, "ACME Ltd.")
+
+	e := NewEntity().
+		SetEntityName("ACME Ltd.").
+		SetRegID("http://acme.example").
+		SetRoles(RoleManifestCreator)
+
+	_, err := e.SetX509Cert(der)
+	require.NoError(t, err)
+
+	es := NewEntities().AddEntity(*e)
+
+	cred, err := es.FindManifestCreatorCredential()
+	require.NoError(t, err)
+	assert.Nil(t, cred)
+}
+
+func TestEntities_FindManifestCreatorCredential_not_found(t *testing.T) {
+	e := NewEntity().
+		SetEntityName("ACME Ltd.").
+		SetRegID("http://acme.example").
+		SetRoles(RoleCreator)
+
+	es := NewEntities().AddEntity(*e)
+
+	_, err := es.FindManifestCreatorCredential()
+	assert.EqualError(t, err, "no entity with role manifestCreator")
+}
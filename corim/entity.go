@@ -0,0 +1,111 @@
+// Copyright 2021 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package corim
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/veraison/corim/comid"
+)
+
+// Entity identifies an organisation or individual that participated in the
+// lifecycle of the information conveyed by a CoRIM document, along with the
+// role(s) it played.
+type Entity struct {
+	EntityName  *EntityName      `cbor:"0,keyasint" json:"name"`
+	RegID       *comid.TaggedURI `cbor:"1,keyasint,omitempty" json:"reg-id,omitempty"`
+	Roles       Roles            `cbor:"2,keyasint" json:"roles"`
+	Credentials Credentials      `cbor:"3,keyasint,omitempty" json:"credentials,omitempty"`
+}
+
+// NewEntity instantiates an empty Entity.
+func NewEntity() *Entity {
+	return &Entity{}
+}
+
+// SetEntityName sets the free-form name of the entity.
+func (o *Entity) SetEntityName(name string) *Entity {
+	if o == nil {
+		return nil
+	}
+	o.EntityName = MustNewStringEntityName(name)
+	return o
+}
+
+// SetRegID sets the registration identifier (URI) of the entity.
+func (o *Entity) SetRegID(uri string) *Entity {
+	if o == nil {
+		return nil
+	}
+	regID := comid.TaggedURI(uri)
+	o.RegID = &regID
+	return o
+}
+
+// SetRoles replaces the list of roles played by the entity.
+func (o *Entity) SetRoles(roles ...Role) *Entity {
+	if o == nil {
+		return nil
+	}
+	o.Roles = roles
+	return o
+}
+
+// Valid checks that the entity is well-formed: it must carry a non-empty
+// name, a non-empty reg-id (if one is set), and at least one known role.
+func (o Entity) Valid() error {
+	if o.EntityName == nil {
+		return errors.New("invalid entity: empty entity-name")
+	}
+
+	if err := o.EntityName.Valid(); err != nil {
+		return fmt.Errorf("invalid entity: %w", err)
+	}
+
+	if o.RegID != nil && o.RegID.Empty() {
+		return errors.New("invalid entity: empty reg-id")
+	}
+
+	if len(o.Roles) == 0 {
+		return errors.New("invalid entity: empty roles")
+	}
+
+	if err := o.Roles.Valid(); err != nil {
+		return fmt.Errorf("invalid entity: %w", err)
+	}
+
+	if err := o.Credentials.Valid(o.EntityName); err != nil {
+		return fmt.Errorf("invalid entity: %w", err)
+	}
+
+	return nil
+}
+
+// Entities is a list of Entity records.
+type Entities []Entity
+
+// NewEntities instantiates an empty Entities list.
+func NewEntities() *Entities {
+	return &Entities{}
+}
+
+// AddEntity appends e to the list.
+func (o *Entities) AddEntity(e Entity) *Entities {
+	if o == nil {
+		return nil
+	}
+	*o = append(*o, e)
+	return o
+}
+
+// Valid checks that every Entity in the list is well-formed.
+func (o Entities) Valid() error {
+	for i, e := range o {
+		if err := e.Valid(); err != nil {
+			return fmt.Errorf("entity at index %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/veraison/corim/comid"
@@ -100,7 +101,7 @@ type testEntityName uint64
 func newTestEntityName(val any) (*EntityName, error) {
 	if val == nil {
 		v := testEntityName(0)
-		return &EntityName{&v}, nil
+		return &EntityName{cached: &v}, nil
 	}
 
 	u, ok := val.(uint64)
@@ -109,7 +110,7 @@ func newTestEntityName(val any) (*EntityName, error) {
 	}
 
 	v := testEntityName(u)
-	return &EntityName{&v}, nil
+	return &EntityName{cached: &v}, nil
 }
 
 func (o testEntityName) Type() string {
@@ -130,7 +131,7 @@ type testEntityNameBadType struct {
 
 func newTestEntityNameBadType(_ any) (*EntityName, error) {
 	v := testEntityNameBadType{testEntityName(7)}
-	return &EntityName{&v}, nil
+	return &EntityName{cached: &v}, nil
 }
 
 func (o testEntityNameBadType) Type() string {
@@ -276,3 +277,98 @@ func Test_MustNewEntityName(t *testing.T) {
 		MustNewEntityName(7, "int")
 	})
 }
+
+func Test_ListEntityNameTypes(t *testing.T) {
+	types := ListEntityNameTypes()
+
+	assert.Contains(t, types, EntityNameTypeInfo{Tag: 32, Name: "string"})
+}
+
+type testEntityNameReplaceable struct {
+	testEntityName
+}
+
+func newTestEntityNameReplaceable(_ any) (*EntityName, error) {
+	v := testEntityNameReplaceable{testEntityName(1)}
+	return &EntityName{cached: &v}, nil
+}
+
+func (o testEntityNameReplaceable) Type() string { return "replaceable" }
+
+func Test_UnregisterEntityNameType(t *testing.T) {
+	err := RegisterEntityNameType(123456, newTestEntityNameReplaceable)
+	require.NoError(t, err)
+	assert.Contains(t, ListEntityNameTypes(), EntityNameTypeInfo{Tag: 123456, Name: "replaceable"})
+
+	err = UnregisterEntityNameType(123456)
+	require.NoError(t, err)
+	assert.NotContains(t, ListEntityNameTypes(), EntityNameTypeInfo{Tag: 123456, Name: "replaceable"})
+
+	err = UnregisterEntityNameType(123456)
+	assert.EqualError(t, err, "tag 123456 is not registered")
+}
+
+func Test_ReplaceEntityNameType(t *testing.T) {
+	err := RegisterEntityNameType(223344, newTestEntityNameReplaceable)
+	require.NoError(t, err)
+	defer func() { _ = UnregisterEntityNameType(223344) }()
+
+	// newTestEntityNameBadType reports its Type() as "string", which
+	// collides with the core type, so replacing it must fail.
+	err = ReplaceEntityNameType(223344, newTestEntityNameBadType)
+	assert.EqualError(t, err, `entity name type with name "string" already exists`)
+
+	err = ReplaceEntityNameType(99999, newTestEntityNameReplaceable)
+	assert.EqualError(t, err, "tag 99999 is not registered")
+
+	err = ReplaceEntityNameType(223344, newTestEntityNameReplaceable)
+	require.NoError(t, err)
+}
+
+func TestEntityName_UnknownTag(t *testing.T) {
+	data, err := cbor.Marshal(cbor.Tag{Number: 777777, Content: uint64(42)})
+	require.NoError(t, err)
+
+	var out EntityName
+	err = out.UnmarshalCBOR(data)
+	require.NoError(t, err)
+
+	tag, raw, ok := out.UnknownTag()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(777777), tag)
+	assert.Equal(t, data, raw)
+
+	assert.Nil(t, out.GetCachedValue())
+	assert.EqualError(t, out.Valid(), "entity name has unregistered tag 777777")
+
+	// re-encoding an unresolved EntityName must round-trip byte-identical
+	reencoded, err := out.MarshalCBOR()
+	require.NoError(t, err)
+	assert.Equal(t, data, reencoded)
+}
+
+func TestEntityName_UnpackInto(t *testing.T) {
+	data, err := cbor.Marshal(cbor.Tag{Number: 777778, Content: uint64(42)})
+	require.NoError(t, err)
+
+	var out EntityName
+	err = out.UnmarshalCBOR(data)
+	require.NoError(t, err)
+
+	_, _, ok := out.UnknownTag()
+	require.True(t, ok)
+
+	var target testEntityName
+	err = out.UnpackInto(&target)
+	require.NoError(t, err)
+
+	assert.Equal(t, testEntityName(42), target)
+	assert.Equal(t, "42", out.String())
+	assert.Equal(t, "test", out.GetCachedValue().Type())
+
+	_, _, ok = out.UnknownTag()
+	assert.False(t, ok)
+
+	err = out.UnpackInto(&target)
+	assert.EqualError(t, err, "entity name is already decoded")
+}
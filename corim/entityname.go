@@ -0,0 +1,477 @@
+// Copyright 2021 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package corim
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/veraison/corim/internal/extensions"
+)
+
+// coreStringEntityNameTag is the tag under which the core spec's bare
+// string entity name is registered. Unlike every other entity name type,
+// values of this type are emitted untagged on the wire.
+const coreStringEntityNameTag = 32
+
+// IEntityNameValue is implemented by the concrete value held inside an
+// EntityName. Profiles register their own implementations via
+// RegisterEntityNameType to extend the core spec's bare string name.
+type IEntityNameValue interface {
+	Type() string
+	String() string
+	Valid() error
+}
+
+// EntityNameFactory instantiates an EntityName wrapping an IEntityNameValue
+// built from val.
+type EntityNameFactory func(val any) (*EntityName, error)
+
+// EntityNameTypeInfo describes a single registered entity name type, as
+// returned by ListEntityNameTypes.
+type EntityNameTypeInfo = extensions.TypeInfo
+
+var entityNameTypes = extensions.NewRegistry[EntityNameFactory]("tag", "entity name")
+
+func init() {
+	if err := registerCoreEntityNameTypes(entityNameTypes); err != nil {
+		panic(err)
+	}
+}
+
+// registerCoreEntityNameTypes seeds reg with the core spec's entity name
+// types. It is shared by the package init() (for the process-wide default
+// registry) and by NewProfile() (for a profile's isolated registry).
+func registerCoreEntityNameTypes(reg *extensions.Registry[EntityNameFactory]) error {
+	return reg.RegisterDefault(coreStringEntityNameTag, "string", NewStringEntityName)
+}
+
+// RegisterEntityNameType registers a factory for decoding and constructing
+// entity names carried under the given CBOR tag. It is typically called
+// from a profile's init() function to extend the set of entity name kinds
+// recognised by this package.
+func RegisterEntityNameType(tag uint64, factory EntityNameFactory) error {
+	name, err := probeEntityNameType(tag, factory)
+	if err != nil {
+		return err
+	}
+
+	return entityNameTypes.Register(tag, name, factory)
+}
+
+// ReplaceEntityNameType overrides the factory (and its associated name)
+// registered under tag, which must already be registered. Use this for a
+// controlled override of a built-in or previously registered entity name
+// type.
+func ReplaceEntityNameType(tag uint64, factory EntityNameFactory) error {
+	name, err := probeEntityNameType(tag, factory)
+	if err != nil {
+		return err
+	}
+
+	return entityNameTypes.Replace(tag, name, factory)
+}
+
+// UnregisterEntityNameType removes a previously registered entity name
+// type, allowing its tag and name to be reused.
+func UnregisterEntityNameType(tag uint64) error {
+	return entityNameTypes.Unregister(tag)
+}
+
+// ListEntityNameTypes returns the {Tag, Name} pairs of every entity name
+// type currently registered, including the core string type.
+func ListEntityNameTypes() []EntityNameTypeInfo {
+	return entityNameTypes.List()
+}
+
+// ResetEntityNameTypes restores the entity name type registry to just the
+// built-in core string type, discarding any custom registrations. This is
+// primarily useful to isolate test cases from one another.
+func ResetEntityNameTypes() {
+	entityNameTypes.Reset()
+}
+
+func probeEntityNameType(tag uint64, factory EntityNameFactory) (string, error) {
+	sample, err := factory(nil)
+	if err != nil {
+		return "", fmt.Errorf("probing entity name factory for tag %d: %w", tag, err)
+	}
+
+	return sample.cached.Type(), nil
+}
+
+// EntityName is a polymorphic, Any-like container for the name of an
+// Entity. The core specification only defines a free-form string name, but
+// profiles may register additional kinds via RegisterEntityNameType.
+//
+// When the CBOR tag carried on the wire is registered, the value is decoded
+// eagerly and cached in GetCachedValue. When it is not (e.g. because the
+// consuming binary has not loaded the profile that registers it yet), the
+// tag number and raw wire bytes are retained instead, so that the value can
+// still be re-emitted byte-identical on re-encode, or decoded later via
+// UnpackInto once the type is registered.
+type EntityName struct {
+	// TypeURL is the registered name of the decoded type, or empty if
+	// TagNumber is unregistered.
+	TypeURL string
+	// TagNumber is the CBOR tag under which the value was (or would be)
+	// carried. It is set even for the core string type, even though
+	// that type's wire encoding omits the tag bytes.
+	TagNumber uint64
+	// Raw retains the complete CBOR-encoded wire representation when
+	// TagNumber is not registered.
+	Raw []byte
+
+	cached IEntityNameValue
+}
+
+// NewEntityName constructs an EntityName of the registered type typ from
+// val.
+func NewEntityName(val any, typ string) (*EntityName, error) {
+	tag, factory, ok := entityNameTypes.LookupByName(typ)
+	if !ok {
+		return nil, fmt.Errorf("unknown entity name type %q", typ)
+	}
+
+	en, err := factory(val)
+	if err != nil {
+		return nil, err
+	}
+
+	en.TypeURL = typ
+	en.TagNumber = tag
+
+	return en, nil
+}
+
+// MustNewEntityName is like NewEntityName but panics on error.
+func MustNewEntityName(val any, typ string) *EntityName {
+	en, err := NewEntityName(val, typ)
+	if err != nil {
+		panic(err)
+	}
+	return en
+}
+
+func newEntityNameFromTag(tag uint64, val any) (*EntityName, error) {
+	name, factory, ok := entityNameTypes.Lookup(tag)
+	if !ok {
+		return nil, fmt.Errorf("unknown entity name tag %d", tag)
+	}
+
+	en, err := factory(val)
+	if err != nil {
+		return nil, err
+	}
+
+	en.TypeURL = name
+	en.TagNumber = tag
+
+	return en, nil
+}
+
+// StringEntityName is the core spec's free-form entity name.
+type StringEntityName string
+
+// Type returns "string".
+func (o StringEntityName) Type() string { return "string" }
+
+// String returns the entity name.
+func (o StringEntityName) String() string { return string(o) }
+
+// Valid checks that the entity name is non-empty.
+func (o StringEntityName) Valid() error {
+	if o == "" {
+		return errors.New("empty entity-name")
+	}
+	return nil
+}
+
+// NewStringEntityName constructs an EntityName wrapping a StringEntityName.
+// val may be nil, a string or a []byte.
+func NewStringEntityName(val any) (*EntityName, error) {
+	var s string
+
+	switch t := val.(type) {
+	case nil:
+		s = ""
+	case string:
+		s = t
+	case []byte:
+		s = string(t)
+	default:
+		return nil, fmt.Errorf("unexpected type for string entity name: %T", val)
+	}
+
+	v := StringEntityName(s)
+
+	return &EntityName{TypeURL: "string", TagNumber: coreStringEntityNameTag, cached: &v}, nil
+}
+
+// MustNewStringEntityName is like NewStringEntityName but panics on error.
+func MustNewStringEntityName(val any) *EntityName {
+	en, err := NewStringEntityName(val)
+	if err != nil {
+		panic(err)
+	}
+	return en
+}
+
+// Valid checks that the wrapped value is well-formed. An EntityName
+// carrying an unregistered tag (see UnknownTag) is not valid until it is
+// resolved via UnpackInto.
+func (o EntityName) Valid() error {
+	if o.cached == nil {
+		if o.Raw != nil {
+			return fmt.Errorf("entity name has unregistered tag %d", o.TagNumber)
+		}
+		return errors.New("empty entity-name")
+	}
+	return o.cached.Valid()
+}
+
+// String returns the human-readable representation of the wrapped value,
+// or a placeholder naming the tag if it is unregistered.
+func (o EntityName) String() string {
+	if o.cached == nil {
+		if o.Raw != nil {
+			return fmt.Sprintf("<entity name: unregistered tag %d>", o.TagNumber)
+		}
+		return ""
+	}
+	return o.cached.String()
+}
+
+// GetCachedValue returns the decoded value, or nil if the EntityName has
+// not been decoded (i.e. it carries an unregistered tag; see UnknownTag).
+func (o EntityName) GetCachedValue() IEntityNameValue {
+	return o.cached
+}
+
+// UnknownTag returns the CBOR tag number and raw wire bytes retained when
+// UnmarshalCBOR encountered a tag that is not registered, along with true.
+// It returns (0, nil, false) once the value has been decoded, either
+// because the tag was registered at decode time or because UnpackInto has
+// since been called.
+func (o EntityName) UnknownTag() (uint64, []byte, bool) {
+	if o.cached != nil || o.Raw == nil {
+		return 0, nil, false
+	}
+	return o.TagNumber, o.Raw, true
+}
+
+// UnpackInto decodes a previously unresolved entity name (see UnknownTag)
+// into target, for late binding after its type has been registered. It
+// fails if the EntityName has already been decoded, or if it is empty.
+func (o *EntityName) UnpackInto(target IEntityNameValue) error {
+	if o.cached != nil {
+		return errors.New("entity name is already decoded")
+	}
+	if o.Raw == nil {
+		return errors.New("no raw entity name to unpack")
+	}
+
+	var t cbor.Tag
+	if err := cbor.Unmarshal(o.Raw, &t); err != nil {
+		return fmt.Errorf("re-parsing raw entity name: %w", err)
+	}
+
+	content, err := cbor.Marshal(t.Content)
+	if err != nil {
+		return err
+	}
+
+	if err := cbor.Unmarshal(content, target); err != nil {
+		return fmt.Errorf("unpacking entity name into %T: %w", target, err)
+	}
+
+	if err := target.Valid(); err != nil {
+		return err
+	}
+
+	o.cached = target
+	o.TypeURL = target.Type()
+	o.Raw = nil
+
+	return nil
+}
+
+// MarshalCBOR encodes the entity name against the process-wide default
+// registry. The core string type is emitted untagged; every other
+// registered type is wrapped in its registered CBOR tag. An EntityName
+// holding an unresolved unregistered tag is re-emitted from its retained
+// raw wire bytes, byte-identical to what was decoded.
+func (o EntityName) MarshalCBOR() ([]byte, error) {
+	return marshalEntityNameCBOR(o, entityNameTypes)
+}
+
+// UnmarshalCBOR decodes the entity name against the process-wide default
+// registry, dispatching on whether the wire representation carries a CBOR
+// tag. A tag that is not currently registered is retained verbatim rather
+// than rejected; see UnknownTag and UnpackInto.
+func (o *EntityName) UnmarshalCBOR(data []byte) error {
+	return unmarshalEntityNameCBOR(o, data, entityNameTypes)
+}
+
+func marshalEntityNameCBOR(o EntityName, reg *extensions.Registry[EntityNameFactory]) ([]byte, error) {
+	if o.cached == nil {
+		if o.Raw != nil {
+			return append([]byte(nil), o.Raw...), nil
+		}
+		return nil, errors.New("empty entity-name")
+	}
+
+	name := o.cached.Type()
+
+	tag, _, ok := reg.LookupByName(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown entity name type %q", name)
+	}
+
+	if tag == coreStringEntityNameTag {
+		return cbor.Marshal(o.cached.String())
+	}
+
+	return cbor.Marshal(cbor.Tag{Number: tag, Content: o.cached})
+}
+
+func unmarshalEntityNameCBOR(o *EntityName, data []byte, reg *extensions.Registry[EntityNameFactory]) error {
+	if len(data) == 0 {
+		return errors.New("empty entity-name")
+	}
+
+	if data[0]>>5 == 6 { // major type 6: tagged value
+		var t cbor.Tag
+		if err := cbor.Unmarshal(data, &t); err != nil {
+			return err
+		}
+
+		name, factory, ok := reg.Lookup(t.Number)
+		if !ok {
+			o.TypeURL = ""
+			o.TagNumber = t.Number
+			o.Raw = append([]byte(nil), data...)
+			o.cached = nil
+			return nil
+		}
+
+		en, err := factory(t.Content)
+		if err != nil {
+			return err
+		}
+
+		o.TypeURL = name
+		o.TagNumber = t.Number
+		o.Raw = nil
+		o.cached = en.cached
+
+		return nil
+	}
+
+	var s string
+	if err := cbor.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	en, err := NewStringEntityName(s)
+	if err != nil {
+		return err
+	}
+
+	o.TypeURL = en.TypeURL
+	o.TagNumber = en.TagNumber
+	o.Raw = nil
+	o.cached = en.cached
+
+	return nil
+}
+
+// MarshalJSON encodes the entity name. The core string type is emitted as a
+// bare JSON string; every other registered type is emitted as a
+// {"type", "value"} object.
+func (o EntityName) MarshalJSON() ([]byte, error) {
+	return marshalEntityNameJSON(o, entityNameTypes)
+}
+
+// UnmarshalJSON decodes the entity name, dispatching on whether the wire
+// representation is a bare string or a {"type", "value"} object.
+func (o *EntityName) UnmarshalJSON(data []byte) error {
+	return unmarshalEntityNameJSON(o, data, entityNameTypes)
+}
+
+func marshalEntityNameJSON(o EntityName, reg *extensions.Registry[EntityNameFactory]) ([]byte, error) {
+	if o.cached == nil {
+		return nil, errors.New("empty entity-name")
+	}
+
+	if _, _, ok := reg.LookupByName(o.cached.Type()); !ok {
+		return nil, fmt.Errorf("unknown entity name type %q", o.cached.Type())
+	}
+
+	if o.cached.Type() == "string" {
+		return json.Marshal(o.cached.String())
+	}
+
+	return json.Marshal(struct {
+		Type  string `json:"type"`
+		Value any    `json:"value"`
+	}{
+		Type:  o.cached.Type(),
+		Value: o.cached,
+	})
+}
+
+func unmarshalEntityNameJSON(o *EntityName, data []byte, reg *extensions.Registry[EntityNameFactory]) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+
+		en, err := NewStringEntityName(s)
+		if err != nil {
+			return err
+		}
+
+		*o = *en
+		return nil
+	}
+
+	var raw struct {
+		Type  string          `json:"type"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	tag, factory, ok := reg.LookupByName(raw.Type)
+	if !ok {
+		return fmt.Errorf("unknown entity name type %q", raw.Type)
+	}
+
+	var val any
+	if err := json.Unmarshal(raw.Value, &val); err != nil {
+		return err
+	}
+	if f, ok := val.(float64); ok {
+		val = uint64(f)
+	}
+
+	en, err := factory(val)
+	if err != nil {
+		return err
+	}
+
+	o.TypeURL = raw.Type
+	o.TagNumber = tag
+	o.Raw = nil
+	o.cached = en.cached
+
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2021 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package corim
+
+import (
+	"github.com/veraison/corim/internal/extensions"
+)
+
+// Profile owns an isolated set of entity name and role type registrations,
+// so that independently loaded profiles do not leak their custom types into
+// one another. Each Profile's registries fall back only to the core spec's
+// defaults, never to the process-wide registrations made via
+// RegisterEntityNameType or RegisterRoleType.
+type Profile struct {
+	entityNameTypes *extensions.Registry[EntityNameFactory]
+	roleTypes       *extensions.Registry[struct{}]
+}
+
+// defaultProfile wraps the package's process-wide registries, so that the
+// top-level RegisterEntityNameType and RegisterRoleType functions are sugar
+// over a Profile.
+var defaultProfile = &Profile{
+	entityNameTypes: entityNameTypes,
+	roleTypes:       roleTypes,
+}
+
+// NewProfile returns a Profile whose registries are seeded with just the
+// core spec's entity name and role types, independent of any registrations
+// made against the default profile or any other Profile.
+func NewProfile() *Profile {
+	p := &Profile{
+		entityNameTypes: extensions.NewRegistry[EntityNameFactory]("tag", "entity name"),
+		roleTypes:       extensions.NewRegistry[struct{}]("role", "role"),
+	}
+
+	if err := registerCoreEntityNameTypes(p.entityNameTypes); err != nil {
+		panic(err)
+	}
+	if err := registerCoreRoleTypes(p.roleTypes); err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+// RegisterEntityNameType registers a factory for decoding and constructing
+// entity names carried under the given CBOR tag, scoped to p.
+func (p *Profile) RegisterEntityNameType(tag uint64, factory EntityNameFactory) error {
+	name, err := probeEntityNameType(tag, factory)
+	if err != nil {
+		return err
+	}
+
+	return p.entityNameTypes.Register(tag, name, factory)
+}
+
+// RegisterRole registers a custom Role value identified by code and
+// associates it with name, scoped to p.
+func (p *Profile) RegisterRole(code Role, name string) error {
+	return p.roleTypes.Register(uint64(code), name, struct{}{})
+}
+
+// Encoder encodes entity names and roles against a single Profile's
+// registries.
+type Encoder struct {
+	profile *Profile
+}
+
+// NewEncoder returns an Encoder scoped to p.
+func (p *Profile) NewEncoder() *Encoder {
+	return &Encoder{profile: p}
+}
+
+// EncodeEntityName encodes name against e's profile.
+func (e *Encoder) EncodeEntityName(name EntityName) ([]byte, error) {
+	return marshalEntityNameCBOR(name, e.profile.entityNameTypes)
+}
+
+// EncodeRole encodes role against e's profile.
+func (e *Encoder) EncodeRole(role Role) ([]byte, error) {
+	if err := roleValid(role, e.profile.roleTypes); err != nil {
+		return nil, err
+	}
+	return role.MarshalCBOR()
+}
+
+// EncodeEntityNameJSON encodes name as JSON against e's profile.
+func (e *Encoder) EncodeEntityNameJSON(name EntityName) ([]byte, error) {
+	return marshalEntityNameJSON(name, e.profile.entityNameTypes)
+}
+
+// EncodeRoleJSON encodes role as JSON against e's profile.
+func (e *Encoder) EncodeRoleJSON(role Role) ([]byte, error) {
+	return marshalRoleJSON(role, e.profile.roleTypes)
+}
+
+// Decoder decodes entity names and roles against a single Profile's
+// registries.
+type Decoder struct {
+	profile *Profile
+}
+
+// NewDecoder returns a Decoder scoped to p.
+func (p *Profile) NewDecoder() *Decoder {
+	return &Decoder{profile: p}
+}
+
+// DecodeEntityName decodes data into an EntityName resolved against d's
+// profile.
+func (d *Decoder) DecodeEntityName(data []byte) (*EntityName, error) {
+	var out EntityName
+	if err := unmarshalEntityNameCBOR(&out, data, d.profile.entityNameTypes); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DecodeRole decodes data into a Role resolved against d's profile.
+func (d *Decoder) DecodeRole(data []byte) (Role, error) {
+	var out Role
+	if err := out.UnmarshalCBOR(data); err != nil {
+		return 0, err
+	}
+
+	if err := roleValid(out, d.profile.roleTypes); err != nil {
+		return 0, err
+	}
+
+	return out, nil
+}
+
+// DecodeEntityNameJSON decodes JSON data into an EntityName resolved
+// against d's profile.
+func (d *Decoder) DecodeEntityNameJSON(data []byte) (*EntityName, error) {
+	var out EntityName
+	if err := unmarshalEntityNameJSON(&out, data, d.profile.entityNameTypes); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DecodeRoleJSON decodes JSON data into a Role resolved against d's
+// profile.
+func (d *Decoder) DecodeRoleJSON(data []byte) (Role, error) {
+	var out Role
+	if err := unmarshalRoleJSON(&out, data, d.profile.roleTypes); err != nil {
+		return 0, err
+	}
+	return out, nil
+}
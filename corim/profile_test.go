@@ -0,0 +1,131 @@
+// Copyright 2021 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package corim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewProfile_isolated(t *testing.T) {
+	p1 := NewProfile()
+	p2 := NewProfile()
+
+	// The same tag may be registered independently in two different
+	// profiles without the two colliding.
+	err := p1.RegisterEntityNameType(300001, newTestEntityName)
+	require.NoError(t, err)
+
+	err = p2.RegisterEntityNameType(300001, newTestEntityName)
+	require.NoError(t, err)
+
+	// ... but it must not leak into the process-wide default registry.
+	assert.NotContains(t, ListEntityNameTypes(), EntityNameTypeInfo{Tag: 300001, Name: "test"})
+}
+
+func Test_Profile_RegisterRole(t *testing.T) {
+	p := NewProfile()
+
+	err := p.RegisterRole(Role(100), "custom")
+	require.NoError(t, err)
+
+	enc := p.NewEncoder()
+	data, err := enc.EncodeRole(Role(100))
+	require.NoError(t, err)
+
+	dec := p.NewDecoder()
+	role, err := dec.DecodeRole(data)
+	require.NoError(t, err)
+	assert.Equal(t, Role(100), role)
+
+	// The custom role is not known to a fresh, unrelated profile.
+	other := NewProfile()
+	_, err = other.NewDecoder().DecodeRole(data)
+	assert.EqualError(t, err, "unknown role 100")
+}
+
+func Test_Profile_EncodeDecode_EntityName(t *testing.T) {
+	// NewEntityName resolves "test" against the process-wide registry.
+	registerTestEntityNameType(t)
+
+	p := NewProfile()
+
+	err := p.RegisterEntityNameType(99994, newTestEntityName)
+	require.NoError(t, err)
+
+	en, err := NewEntityName(uint64(7), "test")
+	require.NoError(t, err)
+
+	enc := p.NewEncoder()
+	data, err := enc.EncodeEntityName(*en)
+	require.NoError(t, err)
+
+	dec := p.NewDecoder()
+	out, err := dec.DecodeEntityName(data)
+	require.NoError(t, err)
+	assert.Equal(t, "7", out.String())
+
+	// Falls back to the core string type even though it was not
+	// explicitly registered against p.
+	coreOut, err := dec.DecodeEntityName([]byte{0x64, 0x74, 0x65, 0x73, 0x74}) // "test"
+	require.NoError(t, err)
+	assert.Equal(t, "test", coreOut.String())
+}
+
+func Test_Profile_RegisterRole_JSON(t *testing.T) {
+	p := NewProfile()
+
+	err := p.RegisterRole(Role(100), "custom")
+	require.NoError(t, err)
+
+	enc := p.NewEncoder()
+	data, err := enc.EncodeRoleJSON(Role(100))
+	require.NoError(t, err)
+	assert.JSONEq(t, `"custom"`, string(data))
+
+	dec := p.NewDecoder()
+	role, err := dec.DecodeRoleJSON(data)
+	require.NoError(t, err)
+	assert.Equal(t, Role(100), role)
+
+	// The custom role is not known to a fresh, unrelated profile.
+	other := NewProfile()
+	_, err = other.NewDecoder().DecodeRoleJSON(data)
+	assert.EqualError(t, err, `unknown role "custom"`)
+}
+
+func Test_Profile_EncodeDecode_EntityName_JSON(t *testing.T) {
+	// NewEntityName resolves "test" against the process-wide registry.
+	registerTestEntityNameType(t)
+
+	p := NewProfile()
+
+	err := p.RegisterEntityNameType(99994, newTestEntityName)
+	require.NoError(t, err)
+
+	en, err := NewEntityName(uint64(7), "test")
+	require.NoError(t, err)
+
+	enc := p.NewEncoder()
+	data, err := enc.EncodeEntityNameJSON(*en)
+	require.NoError(t, err)
+
+	dec := p.NewDecoder()
+	out, err := dec.DecodeEntityNameJSON(data)
+	require.NoError(t, err)
+	assert.Equal(t, "7", out.String())
+
+	// The custom type is not known to a fresh, unrelated profile.
+	other := NewProfile()
+	_, err = other.NewDecoder().DecodeEntityNameJSON(data)
+	assert.EqualError(t, err, `unknown entity name type "test"`)
+
+	// Falls back to the core string type even though it was not
+	// explicitly registered against p.
+	coreOut, err := dec.DecodeEntityNameJSON([]byte(`"test"`))
+	require.NoError(t, err)
+	assert.Equal(t, "test", coreOut.String())
+}
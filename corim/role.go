@@ -0,0 +1,183 @@
+// Copyright 2021 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package corim
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/veraison/corim/internal/extensions"
+)
+
+// Role identifies the function(s) an Entity performs relative to a CoRIM
+// document.
+type Role uint64
+
+const (
+	RoleManifestCreator Role = 1
+	RoleTagCreator      Role = 2
+	RoleCreator         Role = 3
+	RoleMaintainer      Role = 4
+)
+
+// RoleTypeInfo describes a single registered role, as returned by
+// ListRoleTypes.
+type RoleTypeInfo = extensions.TypeInfo
+
+var roleTypes = extensions.NewRegistry[struct{}]("role", "role")
+
+func init() {
+	if err := registerCoreRoleTypes(roleTypes); err != nil {
+		panic(err)
+	}
+}
+
+// registerCoreRoleTypes seeds reg with the core spec's roles. It is shared
+// by the package init() (for the process-wide default registry) and by
+// NewProfile() (for a profile's isolated registry).
+func registerCoreRoleTypes(reg *extensions.Registry[struct{}]) error {
+	core := map[Role]string{
+		RoleManifestCreator: "manifestCreator",
+		RoleTagCreator:      "tagCreator",
+		RoleCreator:         "creator",
+		RoleMaintainer:      "maintainer",
+	}
+
+	for code, name := range core {
+		if err := reg.RegisterDefault(uint64(code), name, struct{}{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RegisterRoleType registers a custom Role value identified by code and
+// associates it with name, for use in the JSON encoding and by
+// Role.String. It mirrors RegisterEntityNameType, allowing profiles to extend
+// the fixed set of roles defined by the core specification.
+func RegisterRoleType(code Role, name string) error {
+	return roleTypes.Register(uint64(code), name, struct{}{})
+}
+
+// ReplaceRoleType overrides the name registered for code, which must
+// already be registered. Use this for a controlled override of a core or
+// previously registered role.
+func ReplaceRoleType(code Role, name string) error {
+	return roleTypes.Replace(uint64(code), name, struct{}{})
+}
+
+// UnregisterRoleType removes a previously registered custom role, allowing
+// its code and name to be reused.
+func UnregisterRoleType(code Role) error {
+	return roleTypes.Unregister(uint64(code))
+}
+
+// ListRoleTypes returns the {Tag, Name} pairs of every role currently
+// registered, including the core roles.
+func ListRoleTypes() []RoleTypeInfo {
+	return roleTypes.List()
+}
+
+// ResetRoleTypes restores the role registry to just the core roles,
+// discarding any custom registrations. This is primarily useful to isolate
+// test cases from one another.
+func ResetRoleTypes() {
+	roleTypes.Reset()
+}
+
+func lookupRoleName(code Role) (string, bool) {
+	name, _, ok := roleTypes.Lookup(uint64(code))
+	return name, ok
+}
+
+func lookupRoleCode(name string) (Role, bool) {
+	tag, _, ok := roleTypes.LookupByName(name)
+	return Role(tag), ok
+}
+
+// String returns the human-readable name of the role, falling back to its
+// bare numeric value if it is neither a core nor a registered role.
+func (o Role) String() string {
+	if name, ok := lookupRoleName(o); ok {
+		return name
+	}
+	return fmt.Sprintf("%d", uint64(o))
+}
+
+func (o Role) valid() error {
+	return roleValid(o, roleTypes)
+}
+
+func roleValid(o Role, reg *extensions.Registry[struct{}]) error {
+	if _, _, ok := reg.Lookup(uint64(o)); !ok {
+		return fmt.Errorf("unknown role %d", uint64(o))
+	}
+	return nil
+}
+
+// MarshalCBOR encodes the role as its numeric code.
+func (o Role) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(uint64(o))
+}
+
+// UnmarshalCBOR decodes the role from its numeric code.
+func (o *Role) UnmarshalCBOR(data []byte) error {
+	var v uint64
+	if err := cbor.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*o = Role(v)
+	return nil
+}
+
+// MarshalJSON encodes the role as its human-readable name, looked up
+// against the process-wide default registry.
+func (o Role) MarshalJSON() ([]byte, error) {
+	return marshalRoleJSON(o, roleTypes)
+}
+
+// UnmarshalJSON decodes the role from its human-readable name, looked up
+// against the process-wide default registry.
+func (o *Role) UnmarshalJSON(data []byte) error {
+	return unmarshalRoleJSON(o, data, roleTypes)
+}
+
+func marshalRoleJSON(o Role, reg *extensions.Registry[struct{}]) ([]byte, error) {
+	name, _, ok := reg.Lookup(uint64(o))
+	if !ok {
+		return nil, fmt.Errorf("unknown role %d", uint64(o))
+	}
+	return json.Marshal(name)
+}
+
+func unmarshalRoleJSON(o *Role, data []byte, reg *extensions.Registry[struct{}]) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+
+	tag, _, ok := reg.LookupByName(name)
+	if !ok {
+		return fmt.Errorf("unknown role %q", name)
+	}
+
+	*o = Role(tag)
+	return nil
+}
+
+// Roles is a list of Role values associated with an Entity.
+type Roles []Role
+
+// Valid checks that every Role in the list is either a core role or has
+// been registered via RegisterRoleType.
+func (o Roles) Valid() error {
+	for i, r := range o {
+		if err := r.valid(); err != nil {
+			return fmt.Errorf("%w at index %d", err, i)
+		}
+	}
+	return nil
+}
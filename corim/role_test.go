@@ -0,0 +1,118 @@
+// Copyright 2021 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package corim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RegisterRoleType(t *testing.T) {
+	err := RegisterRoleType(RoleManifestCreator, "myRole")
+	assert.EqualError(t, err, "role 1 is already registered")
+
+	err = RegisterRoleType(666, "maintainer")
+	assert.EqualError(t, err, `role type with name "maintainer" already exists`)
+
+	registerTestRoleType(t)
+
+	err = RegisterRoleType(666, "anotherName")
+	assert.EqualError(t, err, "role 666 is already registered")
+}
+
+// Since the global register is not reset between tests, use this flag to
+// only register the test role once, mirroring registerTestEntityNameType.
+var testRoleTypeRegistered = false
+
+func registerTestRoleType(t *testing.T) {
+	if !testRoleTypeRegistered {
+		err := RegisterRoleType(666, "vendorRole")
+		require.NoError(t, err)
+
+		testRoleTypeRegistered = true
+	}
+}
+
+func Test_UnregisterRoleType(t *testing.T) {
+	err := RegisterRoleType(667, "tempRole")
+	require.NoError(t, err)
+
+	assert.Equal(t, "tempRole", Role(667).String())
+
+	err = UnregisterRoleType(667)
+	require.NoError(t, err)
+
+	assert.Equal(t, "667", Role(667).String())
+
+	err = UnregisterRoleType(667)
+	assert.EqualError(t, err, "role 667 is not registered")
+}
+
+func TestRole_CBOR(t *testing.T) {
+	registerTestRoleType(t)
+
+	data, err := Role(666).MarshalCBOR()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x19, 0x02, 0x9a}, data)
+
+	var out Role
+	err = out.UnmarshalCBOR(data)
+	require.NoError(t, err)
+	assert.Equal(t, Role(666), out)
+	assert.Equal(t, "vendorRole", out.String())
+}
+
+func TestRole_JSON(t *testing.T) {
+	registerTestRoleType(t)
+
+	data, err := Role(666).MarshalJSON()
+	require.NoError(t, err)
+	assert.Equal(t, `"vendorRole"`, string(data))
+
+	var out Role
+	err = out.UnmarshalJSON(data)
+	require.NoError(t, err)
+	assert.Equal(t, Role(666), out)
+}
+
+func TestRoles_Valid_registered(t *testing.T) {
+	registerTestRoleType(t)
+
+	err := Roles{666}.Valid()
+	assert.NoError(t, err)
+}
+
+func Test_ListRoleTypes(t *testing.T) {
+	types := ListRoleTypes()
+
+	assert.Contains(t, types, RoleTypeInfo{Tag: uint64(RoleManifestCreator), Name: "manifestCreator"})
+}
+
+func Test_ReplaceRoleType(t *testing.T) {
+	err := RegisterRoleType(668, "replaceableRole")
+	require.NoError(t, err)
+	defer func() { _ = UnregisterRoleType(668) }()
+
+	err = ReplaceRoleType(668, "maintainer")
+	assert.EqualError(t, err, `role type with name "maintainer" already exists`)
+
+	err = ReplaceRoleType(669, "otherRole")
+	assert.EqualError(t, err, "role 669 is not registered")
+
+	err = ReplaceRoleType(668, "replacedRole")
+	require.NoError(t, err)
+	assert.Equal(t, "replacedRole", Role(668).String())
+}
+
+// Test_ResetRoleTypes must run last: it discards every custom registration
+// made by the tests above.
+func Test_ResetRoleTypes(t *testing.T) {
+	ResetRoleTypes()
+	testRoleTypeRegistered = false
+
+	assert.Equal(t, "666", Role(666).String())
+	assert.Equal(t, "manifestCreator", RoleManifestCreator.String())
+}
@@ -0,0 +1,183 @@
+// Copyright 2021 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package extensions provides a small, concurrency-safe tag<->name registry
+// shared by every pluggable extension point in the corim module (entity
+// name types, role types, and similar). Each call site instantiates its own
+// Registry, parameterized over whatever payload it needs to associate with
+// a tag (e.g. a decoding factory), so that tag spaces and introspection
+// stay independent across extension points while the bookkeeping logic is
+// written once.
+package extensions
+
+import (
+	"fmt"
+	"sync"
+)
+
+// TypeInfo describes a single entry of a Registry, as returned by List.
+type TypeInfo struct {
+	Tag  uint64
+	Name string
+}
+
+type entry[V any] struct {
+	name  string
+	value V
+}
+
+// Registry is a concurrency-safe tag<->name registry for a pluggable
+// extension point. itemLabel and typeLabel are used to word the registry's
+// error messages (e.g. "tag"/"entity name" or "role"/"role").
+type Registry[V any] struct {
+	itemLabel string
+	typeLabel string
+
+	mu    sync.RWMutex
+	byTag map[uint64]entry[V]
+	byName map[string]uint64
+
+	defaults map[uint64]entry[V]
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry[V any](itemLabel, typeLabel string) *Registry[V] {
+	return &Registry[V]{
+		itemLabel: itemLabel,
+		typeLabel: typeLabel,
+		byTag:     map[uint64]entry[V]{},
+		byName:    map[string]uint64{},
+		defaults:  map[uint64]entry[V]{},
+	}
+}
+
+// RegisterDefault registers tag/name/value as one of the registry's
+// built-in defaults. It is meant to be called during package
+// initialization, before any user registration happens, and is restored by
+// Reset.
+func (r *Registry[V]) RegisterDefault(tag uint64, name string, value V) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.register(tag, name, value); err != nil {
+		return err
+	}
+
+	r.defaults[tag] = r.byTag[tag]
+
+	return nil
+}
+
+// Register adds a new tag/name/value triple to the registry.
+func (r *Registry[V]) Register(tag uint64, name string, value V) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.register(tag, name, value)
+}
+
+func (r *Registry[V]) register(tag uint64, name string, value V) error {
+	if _, ok := r.byTag[tag]; ok {
+		return fmt.Errorf("%s %d is already registered", r.itemLabel, tag)
+	}
+	if _, ok := r.byName[name]; ok {
+		return fmt.Errorf("%s type with name %q already exists", r.typeLabel, name)
+	}
+
+	r.byTag[tag] = entry[V]{name: name, value: value}
+	r.byName[name] = tag
+
+	return nil
+}
+
+// Replace overrides the name and value registered for tag, which must
+// already be registered, without needing to Unregister first.
+func (r *Registry[V]) Replace(tag uint64, name string, value V) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.byTag[tag]
+	if !ok {
+		return fmt.Errorf("%s %d is not registered", r.itemLabel, tag)
+	}
+
+	if name != old.name {
+		if _, ok := r.byName[name]; ok {
+			return fmt.Errorf("%s type with name %q already exists", r.typeLabel, name)
+		}
+		delete(r.byName, old.name)
+		r.byName[name] = tag
+	}
+
+	r.byTag[tag] = entry[V]{name: name, value: value}
+
+	return nil
+}
+
+// Unregister removes tag from the registry.
+func (r *Registry[V]) Unregister(tag uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.byTag[tag]
+	if !ok {
+		return fmt.Errorf("%s %d is not registered", r.itemLabel, tag)
+	}
+
+	delete(r.byTag, tag)
+	delete(r.byName, e.name)
+
+	return nil
+}
+
+// Reset discards every registration that is not one of the built-in
+// defaults registered via RegisterDefault. It is primarily useful in
+// table-driven tests that need a pristine registry between cases.
+func (r *Registry[V]) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byTag = map[uint64]entry[V]{}
+	r.byName = map[string]uint64{}
+
+	for tag, e := range r.defaults {
+		r.byTag[tag] = e
+		r.byName[e.name] = tag
+	}
+}
+
+// Lookup returns the name and value registered under tag.
+func (r *Registry[V]) Lookup(tag uint64) (string, V, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.byTag[tag]
+	return e.name, e.value, ok
+}
+
+// LookupByName returns the tag and value registered under name.
+func (r *Registry[V]) LookupByName(name string) (uint64, V, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tag, ok := r.byName[name]
+	if !ok {
+		var zero V
+		return 0, zero, false
+	}
+
+	return tag, r.byTag[tag].value, true
+}
+
+// List returns the {Tag, Name} pairs of every entry currently registered.
+func (r *Registry[V]) List() []TypeInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]TypeInfo, 0, len(r.byTag))
+	for tag, e := range r.byTag {
+		out = append(out, TypeInfo{Tag: tag, Name: e.name})
+	}
+
+	return out
+}